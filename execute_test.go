@@ -0,0 +1,108 @@
+package omniinterface
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// newReqFunc adapts a method/url into the newReq shape execute expects. It
+// reports NewRequestWithContext's error through the returned func's own error
+// value rather than t.Fatalf, since execute (and this helper) can run on a
+// goroutine other than the test's own.
+func newReqFunc(method, url string) func(ctx context.Context) (*http.Request, error) {
+    return func(ctx context.Context) (*http.Request, error) {
+        return http.NewRequestWithContext(ctx, method, url, nil)
+    }
+}
+
+func TestExecutePersistentRetryableStatusSurfacesError(t *testing.T) {
+    var calls int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&calls, 1)
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+    defer srv.Close()
+
+    omni := &OMNiInterface{
+        RetryPolicy: RetryPolicy{
+            MaxAttempts:    3,
+            InitialBackoff: time.Millisecond,
+            MaxBackoff:     time.Millisecond,
+        },
+    }
+
+    _, err := omni.execute(context.Background(), true, newReqFunc(http.MethodGet, srv.URL))
+    if err == nil {
+        t.Fatal("execute() err = nil after exhausting retries on a persistent 503, want an error")
+    }
+    if got := atomic.LoadInt32(&calls); got != 3 {
+        t.Errorf("server received %d calls, want 3 (MaxAttempts)", got)
+    }
+}
+
+func TestExecuteNonIdempotentPostNotRetried(t *testing.T) {
+    var calls int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&calls, 1)
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+    defer srv.Close()
+
+    omni := &OMNiInterface{
+        RetryPolicy: RetryPolicy{
+            MaxAttempts:    3,
+            InitialBackoff: time.Millisecond,
+            MaxBackoff:     time.Millisecond,
+        },
+    }
+
+    // idempotent=false, so a POST must not be retried even though 503 is retryable.
+    _, err := omni.execute(context.Background(), false, newReqFunc(http.MethodPost, srv.URL))
+    if err != nil {
+        t.Fatalf("execute() err = %v, want nil (non-retried POST returns the single response as-is)", err)
+    }
+    if got := atomic.LoadInt32(&calls); got != 1 {
+        t.Errorf("server received %d calls, want 1 (non-idempotent POST must not be retried)", got)
+    }
+}
+
+func TestExecuteContextCancelledDuringBackoffAbortsImmediately(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+    defer srv.Close()
+
+    omni := &OMNiInterface{
+        RetryPolicy: RetryPolicy{
+            MaxAttempts:    5,
+            InitialBackoff: time.Hour,
+            MaxBackoff:     time.Hour,
+        },
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() {
+        _, err := omni.execute(ctx, true, newReqFunc(http.MethodGet, srv.URL))
+        done <- err
+    }()
+
+    // let the first attempt complete and the retry loop start waiting out the
+    // (hour-long) backoff before cancelling, so the cancellation is observed
+    // mid-wait rather than before the loop even starts.
+    time.Sleep(50 * time.Millisecond)
+    cancel()
+
+    select {
+    case err := <-done:
+        if err != context.Canceled {
+            t.Errorf("execute() err = %v, want context.Canceled", err)
+        }
+    case <-time.After(5 * time.Second):
+        t.Fatal("execute() did not return promptly after ctx was cancelled during backoff")
+    }
+}
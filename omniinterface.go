@@ -52,16 +52,23 @@ package omniinterface
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "errors"
     "io/ioutil"
+    "math"
+    "math/rand"
     "net/http"
     "net/url"
     "os"
     "reflect"
+    "sort"
     "strconv"
     "strings"
+    "sync"
     "time"
+
+    "github.com/STiAT/go-omniinterface/query"
 )
 
 // OMNiInterface is the configuration type holding the OMNIbus server information
@@ -71,6 +78,86 @@ type OMNiInterface struct {
     Password              string
     Timeout               float64
     PayloadStructCacheDir string
+    // RetryPolicy controls automatic retries of transient OMNIbus failures.
+    // The zero value means no retries are performed (a single attempt), matching
+    // the historical behavior of this package.
+    RetryPolicy RetryPolicy
+    // HTTPClient, when non-nil, is used as-is for every outgoing request instead of
+    // the package's default *http.Client. Set this to inject connection pooling,
+    // mTLS transports, or per-tenant credentials.
+    HTTPClient *http.Client
+    // SchemaCache, when non-nil, is used instead of the package's default schema
+    // cache (an in-process tier backed by the on-disk PayloadStructCacheDir).
+    SchemaCache SchemaCache
+    // SchemaTTL bounds how long a cached schema is trusted before it's re-fetched
+    // from the OMNIbus catalog. Only honored by the default SchemaCache; a value
+    // <= 0 means cached schemas never expire on their own.
+    SchemaTTL time.Duration
+
+    middlewares     []Middleware
+    schemaCacheOnce sync.Once
+}
+
+// SchemaCache caches the column types ("string", "integer" or "utc") OMNIbus
+// reports for a db/table pair, so generatePayload doesn't have to hit the
+// catalog endpoint on every POST/PATCH.
+type SchemaCache interface {
+    // Get returns the cached types for db/table, or ok == false if nothing
+    // usable is cached.
+    Get(db, table string) (types map[string]string, ok bool)
+    // Put stores types for db/table.
+    Put(db, table string, types map[string]string)
+    // Invalidate discards any cached types for db/table.
+    Invalidate(db, table string)
+}
+
+// RoundTripFunc is a single step of the outgoing request pipeline, terminating in
+// the underlying http.Client's Do method.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc with additional behavior, such as logging,
+// metrics, tracing, or header injection.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use registers middleware to run, in order, around every outgoing OMNIbus request.
+// The first registered middleware is the outermost, running before and after all
+// the others.
+func (omni *OMNiInterface) Use(mw ...Middleware) {
+    omni.middlewares = append(omni.middlewares, mw...)
+}
+
+// roundTripper composes omni.HTTPClient (or a default *http.Client) with the
+// registered middleware chain into a single RoundTripFunc.
+func (omni *OMNiInterface) roundTripper() RoundTripFunc {
+    client := omni.HTTPClient
+    if client == nil {
+        client = &http.Client{}
+    }
+
+    rt := RoundTripFunc(client.Do)
+    for i := len(omni.middlewares) - 1; i >= 0; i-- {
+        rt = omni.middlewares[i](rt)
+    }
+    return rt
+}
+
+// RetryPolicy configures automatic retries with exponential backoff and jitter
+// for transient OMNIbus failures, such as 5xx responses or dropped connections
+// during object server failover.
+type RetryPolicy struct {
+    // MaxAttempts is the total number of attempts, including the first one.
+    // Values <= 1 disable retries.
+    MaxAttempts int
+    // InitialBackoff is the delay before the first retry. Defaults to 100ms.
+    InitialBackoff time.Duration
+    // MaxBackoff caps the exponential delay between retries before jitter is
+    // added, so a wait can exceed it by up to half. Defaults to 30s.
+    MaxBackoff time.Duration
+    // Multiplier is applied to InitialBackoff on each subsequent retry. Defaults to 2.
+    Multiplier float64
+    // RetryableStatus decides whether an HTTP status code should be retried.
+    // Defaults to 408, 429, 500, 502, 503 and 504.
+    RetryableStatus func(status int) bool
 }
 
 // Request is the defined message intended for the OMNIbus REST API
@@ -81,12 +168,27 @@ type Request struct {
     DBPath string `json:"path"`
     // The filter string is what would be after a WHERE clause in a select statement
     Filter string `json:"filter"`
-    // ColumnData holds the event data we use to insert/update entries.
+    // Query, as an alternative to a raw Filter, builds the filter (and, if Columns
+    // was called, the Collist) from typed, chainable conditions. When set, it takes
+    // precedence over Filter; unknown column names are rejected before the request
+    // ever reaches the network.
+    Query *query.Builder `json:"-"`
+    // ColumnData holds the event data we use to insert/update a single entry.
     // Only used in POST and PATCH
     // From this we'll generate the payload, since the IBM REST API is a bit .. let's say complicated ;-).
+    // It's a convenience alias for a one-row ColumnDataRows; if both are set, ColumnDataRows takes precedence.
     ColumnData map[string]interface{}
+    // ColumnDataRows holds the event data we use to insert/update multiple entries in a
+    // single HTTP round trip. Only used in POST and PATCH. Rows don't need to share the
+    // same set of columns; any column missing on a row is filled with a typed zero value
+    // (0 for integer/utc, "" for string) from the cached schema.
+    ColumnDataRows []map[string]interface{}
     // Collist, only utilized in GET functions
     Collist []string
+    // Idempotent marks a POST (INSERT) as safe to retry automatically. GET, PATCH
+    // and DELETE are retried regardless, since they are naturally idempotent;
+    // a POST is only retried when this is set to true.
+    Idempotent bool `json:"-"`
     // Payload is the OMNIbus payload. It's for internal use, but the json module needs to be able to access it
     Payload map[string]interface{} `json:"payload"`
 }
@@ -94,210 +196,508 @@ type Request struct {
 // Result is the return structure of OMNIbus
 type Result []interface{}
 
-// SendRequest is the main function called to send a request to OMNIbus
+// SendRequest is the main function called to send a request to OMNIbus.
+// It is a convenience wrapper around SendRequestContext using context.Background(),
+// so the call never gets cancelled and is only bound by omni.Timeout.
 func (omni *OMNiInterface) SendRequest(event Request) (Result, error) {
+    return omni.SendRequestContext(context.Background(), event)
+}
+
+// SendRequestContext is the main function called to send a request to OMNIbus.
+// The given ctx is threaded through to every outgoing HTTP call, so callers can
+// cancel an in-flight request or set a per-request deadline. A deadline already
+// present on ctx takes precedence over omni.Timeout.
+func (omni *OMNiInterface) SendRequestContext(ctx context.Context, event Request) (Result, error) {
+    if event.Query != nil {
+        types, err := omni.schemaTypes(ctx, &event)
+        if err != nil {
+            return Result{}, err
+        }
+
+        filter, collist, err := event.Query.Build(types)
+        if err != nil {
+            return Result{}, err
+        }
+        event.Filter = filter
+        if len(collist) > 0 {
+            event.Collist = collist
+        }
+    }
+
     // for POST(INSERT) and PATCH(UPDATE) we need to generate a payload.
     if event.Method == "POST" || event.Method == "PATCH" {
-        err := omni.generatePayload(&event)
+        err := omni.generatePayload(ctx, &event)
         if err != nil {
             return Result{}, err
         }
     }
 
-    return omni.sendOMNIbus(event)
+    return omni.sendOMNIbus(ctx, event)
 }
 
-// generatePayload generates the payload for the given event. It takes a pointer, we don't return anything
-func (omni *OMNiInterface) generatePayload(evt *Request) error {
-    err := omni.getPayloadStructure(evt)
+// withTimeout derives a context bound by omni.Timeout, unless ctx already carries
+// its own deadline, in which case that deadline takes precedence.
+func (omni *OMNiInterface) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+    if _, ok := ctx.Deadline(); ok || omni.Timeout <= 0 {
+        return ctx, func() {}
+    }
+    return context.WithTimeout(ctx, time.Duration(omni.Timeout)*time.Second)
+}
 
-    if err != nil {
-        return err
+// defaultRetryableStatus is used whenever RetryPolicy.RetryableStatus is nil.
+func defaultRetryableStatus(status int) bool {
+    switch status {
+    case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+        return true
     }
+    return false
+}
 
-    dbinfo := strings.Split(evt.DBPath, "/")
+// maxAttempts returns the configured number of attempts, defaulting to 1 (no retries).
+func (omni *OMNiInterface) maxAttempts() int {
+    if omni.RetryPolicy.MaxAttempts > 1 {
+        return omni.RetryPolicy.MaxAttempts
+    }
+    return 1
+}
 
-    // we have the structure file saved, we need that to generate the payload.
-    dat, _ := ioutil.ReadFile(omni.PayloadStructCacheDir + "/" + dbinfo[0] + "." + dbinfo[1] + ".json")
+// retryableStatus returns the configured RetryableStatus func, falling back to defaultRetryableStatus.
+func (omni *OMNiInterface) retryableStatus() func(int) bool {
+    if omni.RetryPolicy.RetryableStatus != nil {
+        return omni.RetryPolicy.RetryableStatus
+    }
+    return defaultRetryableStatus
+}
 
-    var types map[string]interface{}
-    json.Unmarshal(dat, &types)
+// backoff computes the delay before the given retry attempt (0-based), including jitter.
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+    initial := policy.InitialBackoff
+    if initial <= 0 {
+        initial = 100 * time.Millisecond
+    }
+    multiplier := policy.Multiplier
+    if multiplier <= 0 {
+        multiplier = 2
+    }
+    max := policy.MaxBackoff
+    if max <= 0 {
+        max = 30 * time.Second
+    }
+
+    delay := time.Duration(float64(initial) * math.Pow(multiplier, float64(attempt)))
+    if delay > max {
+        delay = max
+    }
+
+    jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+    return delay + jitter
+}
 
-    evt.Payload = make(map[string]interface{})
-    evt.Payload["rowset"] = make(map[string]interface{})
-    evt.Payload["rowset"].(map[string]interface{})["coldesc"] = make([]map[string]interface{}, len(evt.ColumnData))
-    coldesc := evt.Payload["rowset"].(map[string]interface{})["coldesc"]
+// parseRetryAfter parses a Retry-After header, either as a number of seconds or an HTTP-date.
+func parseRetryAfter(header string) time.Duration {
+    if header == "" {
+        return 0
+    }
+    if secs, err := strconv.Atoi(header); err == nil {
+        if secs < 0 {
+            return 0
+        }
+        return time.Duration(secs) * time.Second
+    }
+    if when, err := http.ParseTime(header); err == nil {
+        if d := time.Until(when); d > 0 {
+            return d
+        }
+    }
+    return 0
+}
 
-    evt.Payload["rowset"].(map[string]interface{})["rows"] = make([]map[string]interface{}, 1)
-    rows := evt.Payload["rowset"].(map[string]interface{})["rows"]
-    rows.([]map[string]interface{})[0] = make(map[string]interface{})
+// execute sends the request built by newReq, retrying on transient failures according to
+// omni.RetryPolicy. idempotent controls whether a POST is eligible for retries; every other
+// method is always considered safe to retry. Context cancellation aborts immediately, even
+// while waiting out a backoff delay.
+func (omni *OMNiInterface) execute(ctx context.Context, idempotent bool, newReq func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+    attempts := omni.maxAttempts()
+    retryable := omni.retryableStatus()
+
+    var retryAfter time.Duration
+    var lastErr error
+
+    for attempt := 0; attempt < attempts; attempt++ {
+        if attempt > 0 {
+            wait := retryAfter
+            if wait <= 0 {
+                wait = backoff(omni.RetryPolicy, attempt-1)
+            }
+            timer := time.NewTimer(wait)
+            select {
+            case <-ctx.Done():
+                timer.Stop()
+                return nil, ctx.Err()
+            case <-timer.C:
+            }
+            retryAfter = 0
+        }
 
-    i := 0
-    for k, v := range evt.ColumnData {
-        if types[k] == nil {
-            return errors.New("Column not found: " + k)
+        req, err := newReq(ctx)
+        if err != nil {
+            return nil, err
         }
-        coldesc.([]map[string]interface{})[i] = make(map[string]interface{})
-        coldesc.([]map[string]interface{})[i]["type"] = types[k]
-        coldesc.([]map[string]interface{})[i]["name"] = k
-
-        if types[k] == "integer" || types[k] == "utc" {
-            switch reflect.TypeOf(v).String() {
-            case "string":
-                num, err := strconv.Atoi(v.(string))
-                if err != nil {
-                    return errors.New("Couldn't convert column value to integer: " + v.(string) + " (" + types[k].(string) + ")")
-                }
-                rows.([]map[string]interface{})[0][k] = num
-            case "int64":
-                rows.([]map[string]interface{})[0][k] = v.(int64)
-            case "float64":
-                rows.([]map[string]interface{})[0][k] = int(v.(float64))
-            case "int":
-                rows.([]map[string]interface{})[0][k] = v
-            default:
-                return errors.New("Couldn't convert given parameter: " + k + " which is of type " + reflect.TypeOf(v).String())
+
+        canRetry := req.Method != http.MethodPost || idempotent
+
+        resp, err := omni.roundTripper()(req)
+        if err != nil {
+            lastErr = err
+            if ctx.Err() != nil {
+                return nil, ctx.Err()
+            }
+            if !canRetry || attempt == attempts-1 {
+                return nil, err
             }
+            continue
+        }
 
-        } else {
-            rows.([]map[string]interface{})[0][k] = v
+        if canRetry && retryable(resp.StatusCode) {
+            resp.Body.Close()
+            lastErr = errors.New("OMNIbus: transient error, status " + strconv.Itoa(resp.StatusCode))
+            if attempt < attempts-1 {
+                retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+                continue
+            }
+            // attempts exhausted and the last response is still retryable: report
+            // failure instead of falling through to the success path below, so a
+            // persistent 5xx can't be handed back to the caller as an empty, "ok" Result.
+            return nil, lastErr
         }
 
-        i++
+        body, err := ioutil.ReadAll(resp.Body)
+        resp.Body.Close()
+        return body, err
     }
 
-    return nil
+    return nil, lastErr
 }
 
-func (omni *OMNiInterface) getPayloadStructure(evt *Request) error {
-    err := omni.createCacheDir()
+// schemaTypes returns the column types for evt.DBPath as a column name -> OMNIbus
+// type ("string", "integer" or "utc") map, serving it from the schema cache when
+// possible instead of hitting the catalog on every POST/PATCH.
+func (omni *OMNiInterface) schemaTypes(ctx context.Context, evt *Request) (map[string]string, error) {
+    db, table, err := splitDBPath(evt.DBPath)
+    if err != nil {
+        return nil, err
+    }
+
+    if types, ok := omni.schemaCache().Get(db, table); ok {
+        return types, nil
+    }
+
+    return omni.fetchSchema(ctx, db, table)
+}
 
+// splitDBPath splits a Request.DBPath of the form "db/table" into its two parts.
+func splitDBPath(dbPath string) (db string, table string, err error) {
+    dbinfo := strings.Split(dbPath, "/")
+    if len(dbinfo) != 2 {
+        return "", "", errors.New("DBPath is not a path: Format db/table")
+    }
+    return dbinfo[0], dbinfo[1], nil
+}
+
+// generatePayload generates the payload for the given event. It takes a pointer and sets evt.Payload; only an error is returned
+func (omni *OMNiInterface) generatePayload(ctx context.Context, evt *Request) error {
+    db, table, err := splitDBPath(evt.DBPath)
     if err != nil {
         return err
     }
 
-    // check if the file exists
-    dbinfo := strings.Split(evt.DBPath, "/")
-    if len(dbinfo) != 2 {
-        return errors.New("DBPath is not a path: Format db/table")
+    rows, err := requestRows(evt)
+    if err != nil {
+        return err
     }
-    if _, err := os.Stat(omni.PayloadStructCacheDir + "/" + dbinfo[0] + "." + dbinfo[1] + ".json"); os.IsNotExist(err) {
-        // We do the request directly here, it's a static one, no need to use our facility functions.
-        // Yes, it's hardcoded, but that will only change if the product changes
-        req, _ := http.NewRequest("GET", omni.URL+"/catalog/columns"+"?collist=ColumnName,DataType&filter="+url.QueryEscape("DatabaseName='"+dbinfo[0]+"' AND TableName='"+dbinfo[1]+"'"), nil)
-        req.SetBasicAuth(omni.User, omni.Password)
-        client := &http.Client{Timeout: time.Duration(omni.Timeout) * time.Second}
 
-        resp, err := client.Do(req)
+    types, err := omni.schemaTypes(ctx, evt)
+    if err != nil {
+        return err
+    }
 
+    payload, err := buildRowsetPayload(types, rows)
+    if isColumnNotFoundErr(err) {
+        // the server may have added a column since we cached the schema; refresh once and retry.
+        types, err = omni.fetchSchema(ctx, db, table)
         if err != nil {
             return err
         }
+        payload, err = buildRowsetPayload(types, rows)
+    }
+    if err != nil {
+        return err
+    }
 
-        defer resp.Body.Close()
-        body, _ := ioutil.ReadAll(resp.Body)
+    evt.Payload = payload
+    return nil
+}
 
-        var types map[string]interface{}
-        json.Unmarshal(body, &types)
+// requestRows resolves the rows to submit from a Request, preferring ColumnDataRows
+// and falling back to ColumnData as a single-row convenience alias.
+func requestRows(evt *Request) ([]map[string]interface{}, error) {
+    rows := evt.ColumnDataRows
+    if len(rows) == 0 && evt.ColumnData != nil {
+        rows = []map[string]interface{}{evt.ColumnData}
+    }
+    if len(rows) == 0 {
+        return nil, errors.New("no column data given: set ColumnData or ColumnDataRows")
+    }
+    return rows, nil
+}
 
-        res, err := omni.generateRetval(types)
-        if err != nil {
-            return err
+// isColumnNotFoundErr reports whether err is the "Column not found" error
+// buildRowsetPayload returns for an unrecognized column.
+func isColumnNotFoundErr(err error) bool {
+    return err != nil && strings.HasPrefix(err.Error(), "Column not found: ")
+}
+
+// buildRowsetPayload builds the "rowset" payload OMNIbus expects from rows, using
+// types to resolve each column's OMNIbus type and to validate column names.
+func buildRowsetPayload(types map[string]string, rows []map[string]interface{}) (map[string]interface{}, error) {
+    // the coldesc is built from the union of columns across all rows, so a row
+    // missing a column that a sibling row sets still gets a consistent coldesc.
+    var columns []string
+    seen := make(map[string]bool)
+    for _, row := range rows {
+        for k := range row {
+            if !seen[k] {
+                seen[k] = true
+                columns = append(columns, k)
+            }
         }
+    }
+    sort.Strings(columns)
 
-        for _, v := range res {
-            dt := v.(map[string]interface{})["DataType"].(float64)
-            cn := v.(map[string]interface{})["ColumnName"].(string)
-
-            switch dt {
-            case 1:
-                types[cn] = "utc"
-            case 2:
-                types[cn] = "string"
-            case 10:
-                types[cn] = "string"
-            default:
-                types[cn] = "integer"
+    coldesc := make([]map[string]interface{}, len(columns))
+    for i, k := range columns {
+        t, ok := types[k]
+        if !ok {
+            return nil, errors.New("Column not found: " + k)
+        }
+        coldesc[i] = map[string]interface{}{
+            "type": t,
+            "name": k,
+        }
+    }
+
+    payloadRows := make([]map[string]interface{}, len(rows))
+    for ri, row := range rows {
+        payloadRow := make(map[string]interface{})
+        for _, k := range columns {
+            v, ok := row[k]
+            if !ok {
+                payloadRow[k] = zeroColumnValue(types[k])
+                continue
+            }
+
+            converted, err := convertColumnValue(k, types[k], v)
+            if err != nil {
+                return nil, err
             }
+            payloadRow[k] = converted
         }
+        payloadRows[ri] = payloadRow
+    }
+
+    return map[string]interface{}{
+        "rowset": map[string]interface{}{
+            "coldesc": coldesc,
+            "rows":    payloadRows,
+        },
+    }, nil
+}
 
-        dat, _ := json.Marshal(types)
-        ioutil.WriteFile(omni.PayloadStructCacheDir+"/"+dbinfo[0]+"."+dbinfo[1]+".json", dat, 0644)
+// zeroColumnValue returns the typed zero value used to fill a column a row didn't set.
+func zeroColumnValue(columnType string) interface{} {
+    if columnType == "integer" || columnType == "utc" {
+        return 0
     }
-    return nil
+    return ""
+}
+
+// convertColumnValue converts a caller-supplied column value to the type OMNIbus expects.
+func convertColumnValue(name string, columnType string, v interface{}) (interface{}, error) {
+    if columnType != "integer" && columnType != "utc" {
+        return v, nil
+    }
+
+    if v == nil {
+        return nil, errors.New("Couldn't convert given parameter: " + name + " which is nil")
+    }
+
+    switch reflect.TypeOf(v).String() {
+    case "string":
+        num, err := strconv.Atoi(v.(string))
+        if err != nil {
+            return nil, errors.New("Couldn't convert column value to integer: " + v.(string) + " (" + columnType + ")")
+        }
+        return num, nil
+    case "int64":
+        return v.(int64), nil
+    case "float64":
+        return int(v.(float64)), nil
+    case "int":
+        return v, nil
+    default:
+        return nil, errors.New("Couldn't convert given parameter: " + name + " which is of type " + reflect.TypeOf(v).String())
+    }
+}
+
+// schemaCache lazily builds the default SchemaCache the first time it's needed,
+// unless the caller already assigned one of its own to omni.SchemaCache. The
+// lazy init runs at most once per OMNiInterface, even under concurrent callers,
+// so two goroutines racing on the first POST/PATCH never end up with two
+// *fileSchemaCache instances backing the same cache directory.
+func (omni *OMNiInterface) schemaCache() SchemaCache {
+    omni.schemaCacheOnce.Do(func() {
+        if omni.SchemaCache == nil {
+            omni.createCacheDir()
+            omni.SchemaCache = newFileSchemaCache(omni.PayloadStructCacheDir, omni.SchemaTTL)
+        }
+    })
+    return omni.SchemaCache
 }
 
-func (omni *OMNiInterface) sendOMNIbus(evt Request) (Result, error) {
+// fetchSchema queries the OMNIbus catalog for db.table's column types and stores
+// the result in the schema cache.
+func (omni *OMNiInterface) fetchSchema(ctx context.Context, db string, table string) (map[string]string, error) {
+    if err := omni.createCacheDir(); err != nil {
+        return nil, err
+    }
+
+    ctx, cancel := omni.withTimeout(ctx)
+    defer cancel()
+
+    // We do the request directly here, it's a static one, no need to use our facility functions.
+    // Yes, it's hardcoded, but that will only change if the product changes
+    body, err := omni.execute(ctx, true, func(ctx context.Context) (*http.Request, error) {
+        req, err := http.NewRequestWithContext(ctx, "GET", omni.URL+"/catalog/columns"+"?collist=ColumnName,DataType&filter="+url.QueryEscape("DatabaseName='"+db+"' AND TableName='"+table+"'"), nil)
+        if err != nil {
+            return nil, err
+        }
+        req.SetBasicAuth(omni.User, omni.Password)
+        return req, nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    var raw map[string]interface{}
+    json.Unmarshal(body, &raw)
+
+    res, err := omni.generateRetval(raw)
+    if err != nil {
+        return nil, err
+    }
+
+    types := make(map[string]string, len(res))
+    for _, v := range res {
+        dt := v.(map[string]interface{})["DataType"].(float64)
+        cn := v.(map[string]interface{})["ColumnName"].(string)
+
+        switch dt {
+        case 1:
+            types[cn] = "utc"
+        case 2:
+            types[cn] = "string"
+        case 10:
+            types[cn] = "string"
+        default:
+            types[cn] = "integer"
+        }
+    }
+
+    omni.schemaCache().Put(db, table, types)
+    return types, nil
+}
+
+// RefreshSchema forces a catalog lookup for db/table, bypassing and then
+// repopulating the schema cache, so schema changes made on the OMNIbus server
+// (new columns, altered types) are picked up without operator intervention.
+func (omni *OMNiInterface) RefreshSchema(db string, table string) error {
+    omni.schemaCache().Invalidate(db, table)
+    _, err := omni.fetchSchema(context.Background(), db, table)
+    return err
+}
+
+func (omni *OMNiInterface) sendOMNIbus(ctx context.Context, evt Request) (Result, error) {
+    ctx, cancel := omni.withTimeout(ctx)
+    defer cancel()
+
     // sanity checks
     switch evt.Method {
     case "GET":
         columns := strings.Join(evt.Collist, ",")
-        req, _ := http.NewRequest("GET", omni.URL+"/"+evt.DBPath+"/"+"?collist="+columns+"&filter="+url.QueryEscape(evt.Filter), nil)
-        req.Close = true
-        req.SetBasicAuth(omni.User, omni.Password)
-        client := &http.Client{Timeout: time.Duration(omni.Timeout) * time.Second}
-        resp, err := client.Do(req)
-
+        body, err := omni.execute(ctx, true, func(ctx context.Context) (*http.Request, error) {
+            req, err := http.NewRequestWithContext(ctx, "GET", omni.URL+"/"+evt.DBPath+"/"+"?collist="+columns+"&filter="+url.QueryEscape(evt.Filter), nil)
+            if err != nil {
+                return nil, err
+            }
+            req.Close = true
+            req.SetBasicAuth(omni.User, omni.Password)
+            return req, nil
+        })
         if err != nil {
             return Result{}, err
         }
-        defer resp.Body.Close()
-        body, _ := ioutil.ReadAll(resp.Body)
         var result map[string]interface{}
         json.Unmarshal(body, &result)
         return omni.generateRetval(result)
     case "DELETE":
-        req, _ := http.NewRequest("DELETE", omni.URL+"/"+evt.DBPath+"/"+"?filter="+url.QueryEscape(evt.Filter), nil)
-        req.Close = true
-        req.SetBasicAuth(omni.User, omni.Password)
-        client := &http.Client{Timeout: time.Duration(omni.Timeout) * time.Second}
-        resp, err := client.Do(req)
-
+        body, err := omni.execute(ctx, true, func(ctx context.Context) (*http.Request, error) {
+            req, err := http.NewRequestWithContext(ctx, "DELETE", omni.URL+"/"+evt.DBPath+"/"+"?filter="+url.QueryEscape(evt.Filter), nil)
+            if err != nil {
+                return nil, err
+            }
+            req.Close = true
+            req.SetBasicAuth(omni.User, omni.Password)
+            return req, nil
+        })
         if err != nil {
             return Result{}, err
         }
-        defer resp.Body.Close()
-        body, _ := ioutil.ReadAll(resp.Body)
         var result map[string]interface{}
         json.Unmarshal(body, &result)
         return omni.generateRetval(result)
     case "PATCH":
         jsonstr, _ := json.Marshal(evt.Payload)
-        req, _ := http.NewRequest("PATCH", omni.URL+"/"+evt.DBPath+"?filter="+url.QueryEscape(evt.Filter), bytes.NewBuffer(jsonstr))
-        req.Close = true
-        req.SetBasicAuth(omni.User, omni.Password)
-        req.Header.Set("Content-Type", "application/json")
-
-        client := &http.Client{Timeout: time.Duration(omni.Timeout) * time.Second}
-        resp, err := client.Do(req)
-
+        body, err := omni.execute(ctx, true, func(ctx context.Context) (*http.Request, error) {
+            req, err := http.NewRequestWithContext(ctx, "PATCH", omni.URL+"/"+evt.DBPath+"?filter="+url.QueryEscape(evt.Filter), bytes.NewBuffer(jsonstr))
+            if err != nil {
+                return nil, err
+            }
+            req.Close = true
+            req.SetBasicAuth(omni.User, omni.Password)
+            req.Header.Set("Content-Type", "application/json")
+            return req, nil
+        })
         if err != nil {
             return Result{}, err
         }
-
-        defer resp.Body.Close()
-        body, _ := ioutil.ReadAll(resp.Body)
         var result map[string]interface{}
         json.Unmarshal(body, &result)
         return omni.generateRetval(result)
     case "POST":
         jsonstr, _ := json.Marshal(evt.Payload)
-        req, _ := http.NewRequest("POST", omni.URL+"/"+evt.DBPath, bytes.NewBuffer(jsonstr))
-        req.Close = true
-        req.SetBasicAuth(omni.User, omni.Password)
-        req.Header.Set("Content-Type", "application/json")
-
-        client := &http.Client{Timeout: time.Duration(omni.Timeout) * time.Second}
-
-        resp, err := client.Do(req)
-
+        body, err := omni.execute(ctx, evt.Idempotent, func(ctx context.Context) (*http.Request, error) {
+            req, err := http.NewRequestWithContext(ctx, "POST", omni.URL+"/"+evt.DBPath, bytes.NewBuffer(jsonstr))
+            if err != nil {
+                return nil, err
+            }
+            req.Close = true
+            req.SetBasicAuth(omni.User, omni.Password)
+            req.Header.Set("Content-Type", "application/json")
+            return req, nil
+        })
         if err != nil {
             return Result{}, err
         }
-
-        defer resp.Body.Close()
-
-        body, _ := ioutil.ReadAll(resp.Body)
         var result map[string]interface{}
         json.Unmarshal(body, &result)
         return omni.generateRetval(result)
@@ -305,6 +705,9 @@ func (omni *OMNiInterface) sendOMNIbus(evt Request) (Result, error) {
     return Result{}, nil
 }
 
+// generateRetval turns a raw OMNIbus response into a Result. For every method it returns
+// the "rows" of the response's rowset exactly as OMNIbus sent them, or an empty Result if
+// there are none; it does not build per-row status entries for a POST/PATCH of its own.
 func (omni *OMNiInterface) generateRetval(res map[string]interface{}) (Result, error) {
     // exception by OMNIbus
     if _, ok := res["exception"]; ok {
@@ -340,3 +743,91 @@ func (omni *OMNiInterface) createCacheDir() error {
     // directory did already exist
     return nil
 }
+
+// fileSchemaCache is the default SchemaCache: an in-process sync.Map tier backed
+// by the JSON files PayloadStructCacheDir already holds, so a cold process still
+// finds a previously cached schema without a catalog round trip, while a warm one
+// avoids the per-request file read and JSON unmarshal.
+type fileSchemaCache struct {
+    dir string
+    ttl time.Duration
+    mem sync.Map
+}
+
+type schemaCacheEntry struct {
+    types    map[string]string
+    cachedAt time.Time
+}
+
+func newFileSchemaCache(dir string, ttl time.Duration) *fileSchemaCache {
+    return &fileSchemaCache{dir: dir, ttl: ttl}
+}
+
+func (c *fileSchemaCache) key(db, table string) string {
+    return db + "." + table
+}
+
+func (c *fileSchemaCache) path(db, table string) string {
+    return c.dir + "/" + c.key(db, table) + ".json"
+}
+
+func (c *fileSchemaCache) Get(db, table string) (map[string]string, bool) {
+    key := c.key(db, table)
+
+    if v, ok := c.mem.Load(key); ok {
+        entry := v.(schemaCacheEntry)
+        if c.ttl <= 0 || time.Since(entry.cachedAt) < c.ttl {
+            return entry.types, true
+        }
+        c.mem.Delete(key)
+    }
+
+    // The disk tier is only a cold-start seed, not a second place to silently
+    // keep serving stale data forever: its freshness is judged by the file's own
+    // mtime, so a TTL expiry still forces fetchSchema once the on-disk copy is
+    // also older than SchemaTTL, instead of just re-stamping stale types with
+    // time.Now() and handing them back as if they were fresh.
+    info, err := os.Stat(c.path(db, table))
+    if err != nil {
+        return nil, false
+    }
+    if c.ttl > 0 && time.Since(info.ModTime()) >= c.ttl {
+        return nil, false
+    }
+
+    dat, err := ioutil.ReadFile(c.path(db, table))
+    if err != nil {
+        return nil, false
+    }
+
+    var raw map[string]interface{}
+    if err := json.Unmarshal(dat, &raw); err != nil {
+        return nil, false
+    }
+
+    types := make(map[string]string, len(raw))
+    for k, v := range raw {
+        if s, ok := v.(string); ok {
+            types[k] = s
+        }
+    }
+
+    c.mem.Store(key, schemaCacheEntry{types: types, cachedAt: info.ModTime()})
+    return types, true
+}
+
+func (c *fileSchemaCache) Put(db, table string, types map[string]string) {
+    key := c.key(db, table)
+    c.mem.Store(key, schemaCacheEntry{types: types, cachedAt: time.Now()})
+
+    dat, err := json.Marshal(types)
+    if err != nil {
+        return
+    }
+    ioutil.WriteFile(c.path(db, table), dat, 0644)
+}
+
+func (c *fileSchemaCache) Invalidate(db, table string) {
+    c.mem.Delete(c.key(db, table))
+    os.Remove(c.path(db, table))
+}
@@ -0,0 +1,171 @@
+package omniinterface
+
+import (
+    "net/http"
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestBackoff(t *testing.T) {
+    policy := RetryPolicy{
+        InitialBackoff: 100 * time.Millisecond,
+        MaxBackoff:     1 * time.Second,
+        Multiplier:     2,
+    }
+
+    cases := []struct {
+        attempt  int
+        min, max time.Duration
+    }{
+        {0, 100 * time.Millisecond, 150 * time.Millisecond},
+        {1, 200 * time.Millisecond, 300 * time.Millisecond},
+        {5, 1 * time.Second, 1500 * time.Millisecond}, // capped at MaxBackoff before jitter
+    }
+
+    for _, c := range cases {
+        // jitter is random, so sample a few times and check every sample stays in range.
+        for i := 0; i < 20; i++ {
+            got := backoff(policy, c.attempt)
+            if got < c.min || got > c.max {
+                t.Fatalf("backoff(attempt=%d) = %v, want in [%v, %v]", c.attempt, got, c.min, c.max)
+            }
+        }
+    }
+}
+
+func TestBackoffDefaults(t *testing.T) {
+    // a zero-value RetryPolicy should still produce a sane, bounded delay.
+    got := backoff(RetryPolicy{}, 0)
+    if got <= 0 || got > 30*time.Second {
+        t.Fatalf("backoff with zero-value policy = %v, want in (0, 30s]", got)
+    }
+}
+
+func TestParseRetryAfter(t *testing.T) {
+    cases := []struct {
+        name   string
+        header string
+        want   time.Duration
+    }{
+        {"empty", "", 0},
+        {"seconds", "5", 5 * time.Second},
+        {"negative seconds", "-1", 0},
+        {"garbage", "not-a-date", 0},
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            if got := parseRetryAfter(c.header); got != c.want {
+                t.Errorf("parseRetryAfter(%q) = %v, want %v", c.header, got, c.want)
+            }
+        })
+    }
+
+    t.Run("future http-date", func(t *testing.T) {
+        when := time.Now().Add(1 * time.Hour)
+        got := parseRetryAfter(when.Format(http.TimeFormat))
+        if got <= 0 || got > time.Hour {
+            t.Errorf("parseRetryAfter(future date) = %v, want in (0, 1h]", got)
+        }
+    })
+
+    t.Run("past http-date", func(t *testing.T) {
+        when := time.Now().Add(-1 * time.Hour)
+        if got := parseRetryAfter(when.Format(http.TimeFormat)); got != 0 {
+            t.Errorf("parseRetryAfter(past date) = %v, want 0", got)
+        }
+    })
+}
+
+func TestBuildRowsetPayloadZeroFillsMissingColumns(t *testing.T) {
+    types := map[string]string{
+        "Node":     "string",
+        "Severity": "integer",
+    }
+    rows := []map[string]interface{}{
+        {"Node": "node-a", "Severity": "5"},
+        {"Node": "node-b"},
+    }
+
+    payload, err := buildRowsetPayload(types, rows)
+    if err != nil {
+        t.Fatalf("buildRowsetPayload: %v", err)
+    }
+
+    rowset := payload["rowset"].(map[string]interface{})
+    coldesc := rowset["coldesc"].([]map[string]interface{})
+    if len(coldesc) != 2 {
+        t.Fatalf("coldesc has %d entries, want 2", len(coldesc))
+    }
+    // columns are sorted, so Node comes before Severity.
+    if coldesc[0]["name"] != "Node" || coldesc[1]["name"] != "Severity" {
+        t.Fatalf("coldesc = %v, want Node then Severity", coldesc)
+    }
+
+    payloadRows := rowset["rows"].([]map[string]interface{})
+    if len(payloadRows) != 2 {
+        t.Fatalf("rows has %d entries, want 2", len(payloadRows))
+    }
+    if payloadRows[0]["Severity"] != 5 {
+        t.Errorf("row 0 Severity = %v, want 5 (converted from string)", payloadRows[0]["Severity"])
+    }
+    if payloadRows[1]["Severity"] != 0 {
+        t.Errorf("row 1 Severity = %v, want 0 (zero-filled)", payloadRows[1]["Severity"])
+    }
+    if payloadRows[1]["Node"] != "node-b" {
+        t.Errorf("row 1 Node = %v, want node-b", payloadRows[1]["Node"])
+    }
+}
+
+func TestBuildRowsetPayloadUnknownColumn(t *testing.T) {
+    types := map[string]string{"Node": "string"}
+    rows := []map[string]interface{}{{"Node": "node-a", "NotAColumn": "x"}}
+
+    _, err := buildRowsetPayload(types, rows)
+    if err == nil {
+        t.Fatal("expected an error for an unknown column, got nil")
+    }
+    if want := "Column not found: NotAColumn"; err.Error() != want {
+        t.Errorf("err = %q, want %q", err.Error(), want)
+    }
+}
+
+func TestConvertColumnValue(t *testing.T) {
+    cases := []struct {
+        name       string
+        columnType string
+        value      interface{}
+        want       interface{}
+        wantErr    bool
+    }{
+        {"string passthrough", "string", "hello", "hello", false},
+        {"integer from string", "integer", "42", 42, false},
+        {"integer from float64", "utc", float64(42), 42, false},
+        {"integer from int64", "integer", int64(42), int64(42), false},
+        {"integer from int", "integer", 42, 42, false},
+        {"bad string", "integer", "not-a-number", nil, true},
+        {"unsupported type", "integer", true, nil, true},
+        {"nil for integer column", "integer", nil, nil, true},
+        {"nil for utc column", "utc", nil, nil, true},
+        {"nil for string column", "string", nil, nil, false},
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            got, err := convertColumnValue("Col", c.columnType, c.value)
+            if c.wantErr {
+                if err == nil {
+                    t.Fatalf("convertColumnValue(%q, %v) = %v, nil; want an error", c.columnType, c.value, got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("convertColumnValue(%q, %v) returned unexpected error: %v", c.columnType, c.value, err)
+            }
+            if !reflect.DeepEqual(got, c.want) {
+                t.Errorf("convertColumnValue(%q, %v) = %v, want %v", c.columnType, c.value, got, c.want)
+            }
+        })
+    }
+}
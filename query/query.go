@@ -0,0 +1,197 @@
+// Package query provides a typed, fluent builder for OMNIbus REST API filters,
+// replacing hand-escaped raw `Filter` strings with a composable API.
+package query
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// Order selects the sort direction for an OrderBy clause.
+type Order int
+
+// Sort directions accepted by OrderBy.
+const (
+    Asc Order = iota
+    Desc
+)
+
+type op string
+
+const (
+    opEq op = "="
+    opNe op = "!="
+    opGt op = ">"
+    opGte op = ">="
+    opLt op = "<"
+    opLte op = "<="
+)
+
+type condition struct {
+    column string
+    op     op
+    value  interface{}
+}
+
+type inCondition struct {
+    column string
+    values []interface{}
+}
+
+type orderClause struct {
+    column string
+    order  Order
+}
+
+// Builder builds an OMNIbus WHERE filter fragment (and an optional Collist) from
+// typed, chainable conditions. Column names are validated against a schema type
+// map when Build is called, and values are quoted according to their column's type,
+// so callers never hand-escape a raw filter string themselves. Values for integer
+// and utc columns are written as formatted, without checking that they are numeric.
+type Builder struct {
+    conditions   []condition
+    inConditions []inCondition
+    columns      []string
+    orderBy      []orderClause
+    limit        int
+}
+
+// New starts a new, empty Builder.
+func New() *Builder {
+    return &Builder{}
+}
+
+// Eq adds a "column = value" condition.
+func (b *Builder) Eq(column string, value interface{}) *Builder {
+    b.conditions = append(b.conditions, condition{column, opEq, value})
+    return b
+}
+
+// Ne adds a "column != value" condition.
+func (b *Builder) Ne(column string, value interface{}) *Builder {
+    b.conditions = append(b.conditions, condition{column, opNe, value})
+    return b
+}
+
+// Gt adds a "column > value" condition.
+func (b *Builder) Gt(column string, value interface{}) *Builder {
+    b.conditions = append(b.conditions, condition{column, opGt, value})
+    return b
+}
+
+// Gte adds a "column >= value" condition.
+func (b *Builder) Gte(column string, value interface{}) *Builder {
+    b.conditions = append(b.conditions, condition{column, opGte, value})
+    return b
+}
+
+// Lt adds a "column < value" condition.
+func (b *Builder) Lt(column string, value interface{}) *Builder {
+    b.conditions = append(b.conditions, condition{column, opLt, value})
+    return b
+}
+
+// Lte adds a "column <= value" condition.
+func (b *Builder) Lte(column string, value interface{}) *Builder {
+    b.conditions = append(b.conditions, condition{column, opLte, value})
+    return b
+}
+
+// In adds a "column IN (values...)" condition.
+func (b *Builder) In(column string, values ...interface{}) *Builder {
+    b.inConditions = append(b.inConditions, inCondition{column, values})
+    return b
+}
+
+// Columns sets the Collist returned alongside the filter. Without a call to
+// Columns, Build returns a nil Collist, meaning "all columns" to the REST API.
+func (b *Builder) Columns(columns ...string) *Builder {
+    b.columns = append(b.columns, columns...)
+    return b
+}
+
+// OrderBy appends a sort clause, evaluated in the order the calls were made.
+func (b *Builder) OrderBy(column string, order Order) *Builder {
+    b.orderBy = append(b.orderBy, orderClause{column, order})
+    return b
+}
+
+// Limit caps the number of rows returned. A value <= 0 means no limit.
+func (b *Builder) Limit(n int) *Builder {
+    b.limit = n
+    return b
+}
+
+// Build resolves the builder against types, a column name -> OMNIbus type
+// ("string", "integer" or "utc") map, and returns the resulting Filter fragment
+// and Collist. It rejects any column that isn't present in types, giving the
+// same "Column not found" error surface as a POST/PATCH payload.
+func (b *Builder) Build(types map[string]string) (filter string, collist []string, err error) {
+    var parts []string
+
+    for _, c := range b.conditions {
+        t, ok := types[c.column]
+        if !ok {
+            return "", nil, columnNotFound(c.column)
+        }
+        parts = append(parts, c.column+" "+string(c.op)+" "+formatValue(t, c.value))
+    }
+
+    for _, c := range b.inConditions {
+        t, ok := types[c.column]
+        if !ok {
+            return "", nil, columnNotFound(c.column)
+        }
+        values := make([]string, len(c.values))
+        for i, v := range c.values {
+            values[i] = formatValue(t, v)
+        }
+        parts = append(parts, c.column+" IN ("+strings.Join(values, ", ")+")")
+    }
+
+    for _, col := range b.columns {
+        if _, ok := types[col]; !ok {
+            return "", nil, columnNotFound(col)
+        }
+    }
+
+    for _, o := range b.orderBy {
+        if _, ok := types[o.column]; !ok {
+            return "", nil, columnNotFound(o.column)
+        }
+    }
+
+    filter = strings.Join(parts, " AND ")
+
+    if len(b.orderBy) > 0 {
+        orders := make([]string, len(b.orderBy))
+        for i, o := range b.orderBy {
+            direction := "ASC"
+            if o.order == Desc {
+                direction = "DESC"
+            }
+            orders[i] = o.column + " " + direction
+        }
+        filter += " ORDER BY " + strings.Join(orders, ", ")
+    }
+
+    if b.limit > 0 {
+        filter += fmt.Sprintf(" LIMIT %d", b.limit)
+    }
+
+    return filter, b.columns, nil
+}
+
+func columnNotFound(column string) error {
+    return errors.New("Column not found: " + column)
+}
+
+// formatValue quotes a value for inclusion in a filter fragment according to t,
+// the OMNIbus type resolved from the cached schema.
+func formatValue(t string, v interface{}) string {
+    if t == "integer" || t == "utc" {
+        return fmt.Sprintf("%v", v)
+    }
+    return "'" + strings.ReplaceAll(fmt.Sprintf("%v", v), "'", "''") + "'"
+}
@@ -0,0 +1,122 @@
+package query
+
+import "testing"
+
+var types = map[string]string{
+    "Node":     "string",
+    "Severity": "integer",
+    "LastOccurrence": "utc",
+}
+
+func TestBuilderConditions(t *testing.T) {
+    filter, collist, err := New().
+        Eq("Node", "host1").
+        Ne("Severity", 1).
+        Gt("Severity", 2).
+        Gte("Severity", 3).
+        Lt("Severity", 4).
+        Lte("Severity", 5).
+        Build(types)
+    if err != nil {
+        t.Fatalf("Build: %v", err)
+    }
+    if collist != nil {
+        t.Errorf("collist = %v, want nil", collist)
+    }
+
+    want := "Node = 'host1' AND Severity != 1 AND Severity > 2 AND Severity >= 3 AND Severity < 4 AND Severity <= 5"
+    if filter != want {
+        t.Errorf("filter = %q, want %q", filter, want)
+    }
+}
+
+func TestBuilderIn(t *testing.T) {
+    filter, _, err := New().In("Severity", 1, 2, 3).Build(types)
+    if err != nil {
+        t.Fatalf("Build: %v", err)
+    }
+    if want := "Severity IN (1, 2, 3)"; filter != want {
+        t.Errorf("filter = %q, want %q", filter, want)
+    }
+}
+
+func TestBuilderStringQuoting(t *testing.T) {
+    filter, _, err := New().Eq("Node", "O'Brien").Build(types)
+    if err != nil {
+        t.Fatalf("Build: %v", err)
+    }
+    if want := "Node = 'O''Brien'"; filter != want {
+        t.Errorf("filter = %q, want %q", filter, want)
+    }
+}
+
+func TestBuilderColumnsOrderByLimit(t *testing.T) {
+    filter, collist, err := New().
+        Eq("Node", "host1").
+        Columns("Node", "Severity").
+        OrderBy("Severity", Desc).
+        OrderBy("Node", Asc).
+        Limit(10).
+        Build(types)
+    if err != nil {
+        t.Fatalf("Build: %v", err)
+    }
+
+    if want := []string{"Node", "Severity"}; !equalStrings(collist, want) {
+        t.Errorf("collist = %v, want %v", collist, want)
+    }
+
+    want := "Node = 'host1' ORDER BY Severity DESC, Node ASC LIMIT 10"
+    if filter != want {
+        t.Errorf("filter = %q, want %q", filter, want)
+    }
+}
+
+func TestBuilderUnknownColumn(t *testing.T) {
+    cases := []struct {
+        name string
+        b    *Builder
+    }{
+        {"condition", New().Eq("Missing", 1)},
+        {"in-condition", New().In("Missing", 1)},
+        {"columns", New().Columns("Missing")},
+        {"order by", New().OrderBy("Missing", Asc)},
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            _, _, err := c.b.Build(types)
+            if err == nil {
+                t.Fatal("expected an error, got nil")
+            }
+            if want := "Column not found: Missing"; err.Error() != want {
+                t.Errorf("err = %q, want %q", err.Error(), want)
+            }
+        })
+    }
+}
+
+func TestBuilderEmpty(t *testing.T) {
+    filter, collist, err := New().Build(types)
+    if err != nil {
+        t.Fatalf("Build: %v", err)
+    }
+    if filter != "" {
+        t.Errorf("filter = %q, want empty", filter)
+    }
+    if collist != nil {
+        t.Errorf("collist = %v, want nil", collist)
+    }
+}
+
+func equalStrings(a, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,139 @@
+package omniinterface
+
+import (
+    "os"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestFileSchemaCachePutGet(t *testing.T) {
+    dir := t.TempDir()
+    c := newFileSchemaCache(dir, time.Hour)
+
+    types := map[string]string{"Node": "string", "Severity": "integer"}
+    c.Put("status", "alerts", types)
+
+    got, ok := c.Get("status", "alerts")
+    if !ok {
+        t.Fatal("Get() ok = false, want true")
+    }
+    if got["Node"] != "string" || got["Severity"] != "integer" {
+        t.Errorf("Get() = %v, want %v", got, types)
+    }
+}
+
+func TestFileSchemaCacheColdStartFromDisk(t *testing.T) {
+    dir := t.TempDir()
+
+    // seed the disk tier the way Put would, without ever touching mem.
+    seed := newFileSchemaCache(dir, time.Hour)
+    seed.Put("status", "alerts", map[string]string{"Node": "string"})
+
+    // a fresh cache instance, as a cold process would construct, should still
+    // find the schema on disk without a mem entry.
+    cold := newFileSchemaCache(dir, time.Hour)
+    got, ok := cold.Get("status", "alerts")
+    if !ok {
+        t.Fatal("Get() ok = false, want true (cold-start from disk)")
+    }
+    if got["Node"] != "string" {
+        t.Errorf("Get() = %v, want Node=string", got)
+    }
+}
+
+func TestFileSchemaCacheMissingEntry(t *testing.T) {
+    c := newFileSchemaCache(t.TempDir(), time.Hour)
+    if _, ok := c.Get("status", "alerts"); ok {
+        t.Error("Get() ok = true for an entry that was never Put, want false")
+    }
+}
+
+func TestFileSchemaCacheInvalidate(t *testing.T) {
+    dir := t.TempDir()
+    c := newFileSchemaCache(dir, time.Hour)
+    c.Put("status", "alerts", map[string]string{"Node": "string"})
+
+    c.Invalidate("status", "alerts")
+
+    if _, ok := c.Get("status", "alerts"); ok {
+        t.Error("Get() ok = true after Invalidate, want false")
+    }
+    if _, err := os.Stat(c.path("status", "alerts")); err == nil {
+        t.Error("schema file still exists after Invalidate, want it removed")
+    }
+}
+
+// TestFileSchemaCacheTTLExpiryForcesRefetch verifies the bug the review flagged:
+// once both the mem entry and the on-disk file are older than the TTL, Get must
+// report a miss instead of re-stamping the stale disk copy as fresh forever.
+func TestFileSchemaCacheTTLExpiryForcesRefetch(t *testing.T) {
+    dir := t.TempDir()
+    c := newFileSchemaCache(dir, 10*time.Millisecond)
+    c.Put("status", "alerts", map[string]string{"Node": "string"})
+
+    // age both the mem entry and the backing file past the TTL without sleeping.
+    stale := time.Now().Add(-time.Hour)
+    c.mem.Store(c.key("status", "alerts"), schemaCacheEntry{
+        types:    map[string]string{"Node": "string"},
+        cachedAt: stale,
+    })
+    if err := os.Chtimes(c.path("status", "alerts"), stale, stale); err != nil {
+        t.Fatalf("os.Chtimes: %v", err)
+    }
+
+    if _, ok := c.Get("status", "alerts"); ok {
+        t.Error("Get() ok = true for a TTL-expired entry whose disk copy is also stale, want false so fetchSchema runs")
+    }
+}
+
+// TestFileSchemaCacheDiskStillFreshAfterMemExpiry covers the case where only the
+// mem tier has expired but the disk file was written recently enough to still be
+// within TTL: Get should reload from disk rather than forcing a refetch.
+func TestFileSchemaCacheDiskStillFreshAfterMemExpiry(t *testing.T) {
+    dir := t.TempDir()
+    c := newFileSchemaCache(dir, time.Hour)
+    c.Put("status", "alerts", map[string]string{"Node": "string"})
+
+    // expire only the mem entry; the file on disk keeps its recent mtime.
+    c.mem.Store(c.key("status", "alerts"), schemaCacheEntry{
+        types:    map[string]string{"Node": "string"},
+        cachedAt: time.Now().Add(-2 * time.Hour),
+    })
+
+    got, ok := c.Get("status", "alerts")
+    if !ok {
+        t.Fatal("Get() ok = false, want true (disk copy is still within TTL)")
+    }
+    if got["Node"] != "string" {
+        t.Errorf("Get() = %v, want Node=string", got)
+    }
+}
+
+// TestOMNiInterfaceSchemaCacheConcurrentInit guards against the race the review
+// flagged: concurrent first callers lazily building omni.SchemaCache must all
+// end up sharing exactly one *fileSchemaCache instance, not racing on the field
+// or each building their own cache backed by the same directory.
+func TestOMNiInterfaceSchemaCacheConcurrentInit(t *testing.T) {
+    omni := &OMNiInterface{PayloadStructCacheDir: t.TempDir()}
+
+    const goroutines = 20
+    caches := make([]SchemaCache, goroutines)
+
+    var wg sync.WaitGroup
+    wg.Add(goroutines)
+    for i := 0; i < goroutines; i++ {
+        go func(i int) {
+            defer wg.Done()
+            caches[i] = omni.schemaCache()
+        }(i)
+    }
+    wg.Wait()
+
+    first := caches[0]
+    for i, c := range caches {
+        if c != first {
+            t.Fatalf("caches[%d] = %p, want %p (all callers should share one instance)", i, c, first)
+        }
+    }
+}